@@ -0,0 +1,91 @@
+package gee
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// BindError is returned by Bind/BindJSON/BindQuery/BindForm when
+// decoding or validation fails, so middleware can map it to a 400
+// without inspecting error strings.
+type BindError struct {
+	// Field is the struct field that failed a "binding" tag; empty when
+	// the failure was decoding the body/values rather than validating
+	// them.
+	Field string
+	Err   error
+}
+
+func (e *BindError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *BindError) Unwrap() error { return e.Err }
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}
+
+// BindJSON decodes the request body as JSON into v, then validates it
+// against any "binding" struct tags.
+func (c *Context) BindJSON(v interface{}) error {
+	if err := json.NewDecoder(c.Req.Body).Decode(v); err != nil {
+		return &BindError{Err: err}
+	}
+	return validateStruct(v)
+}
+
+// BindQuery populates v from the URL query string, matching each field's
+// "form" tag (falling back to its Go name), then validates it.
+func (c *Context) BindQuery(v interface{}) error {
+	if err := decodeValues(c.Req.URL.Query(), v); err != nil {
+		return &BindError{Err: err}
+	}
+	return validateStruct(v)
+}
+
+// BindForm populates v from the request's form values - the query
+// string plus a urlencoded or multipart body - then validates it.
+func (c *Context) BindForm(v interface{}) error {
+	if err := c.Req.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+		return &BindError{Err: err}
+	}
+	if err := decodeValues(c.Req.Form, v); err != nil {
+		return &BindError{Err: err}
+	}
+	return validateStruct(v)
+}
+
+// Bind picks BindJSON, BindForm, or BindQuery based on the request's
+// Content-Type, defaulting to BindQuery for requests with no body.
+func (c *Context) Bind(v interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Req.Header.Get(ContentType))
+	switch mediaType {
+	case "application/json":
+		return c.BindJSON(v)
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return c.BindForm(v)
+	default:
+		return c.BindQuery(v)
+	}
+}
+
+func validateStruct(v interface{}) error {
+	if err := validate.Struct(v); err != nil {
+		if verrs, ok := err.(validator.ValidationErrors); ok && len(verrs) > 0 {
+			return &BindError{Field: verrs[0].Field(), Err: verrs}
+		}
+		return &BindError{Err: err}
+	}
+	return nil
+}
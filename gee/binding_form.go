@@ -0,0 +1,78 @@
+package gee
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// decodeValues populates the fields of the struct v points to from
+// values, matching each field's "form" tag (falling back to its Go name)
+// against the first value for that key. Fields with no matching key are
+// left untouched.
+func decodeValues(values url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("gee: bind target must be a pointer to a struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		tag := field.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+		key := field.Name
+		if tag != "" {
+			key = tag
+		}
+		raw, ok := values[key]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+		if err := setField(rv.Field(i), raw[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, raw string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return errors.New("gee: unsupported bind field type " + fv.Kind().String())
+	}
+	return nil
+}
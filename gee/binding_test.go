@@ -0,0 +1,146 @@
+package gee
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeValues(t *testing.T) {
+	type target struct {
+		Name   string  `form:"name"`
+		Age    int     `form:"age"`
+		Score  float64 `form:"score"`
+		Active bool    `form:"active"`
+		Hidden string  `form:"-"`
+		Plain  string
+	}
+
+	values := url.Values{
+		"name":   {"geektutu"},
+		"age":    {"18"},
+		"score":  {"9.5"},
+		"active": {"true"},
+		"Hidden": {"should not be set"},
+		"Plain":  {"matched by field name"},
+	}
+
+	var got target
+	if err := decodeValues(values, &got); err != nil {
+		t.Fatalf("decodeValues() error = %v", err)
+	}
+
+	want := target{
+		Name:   "geektutu",
+		Age:    18,
+		Score:  9.5,
+		Active: true,
+		Plain:  "matched by field name",
+	}
+	if got != want {
+		t.Errorf("decodeValues() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeValuesMissingKeyLeavesZeroValue(t *testing.T) {
+	type target struct {
+		Name string `form:"name"`
+	}
+	var got target
+	if err := decodeValues(url.Values{}, &got); err != nil {
+		t.Fatalf("decodeValues() error = %v", err)
+	}
+	if got.Name != "" {
+		t.Errorf("decodeValues() left Name = %q, want empty", got.Name)
+	}
+}
+
+func TestDecodeValuesRejectsNonStructPointer(t *testing.T) {
+	var s string
+	if err := decodeValues(url.Values{"name": {"x"}}, &s); err == nil {
+		t.Error("decodeValues() with non-struct target = nil error, want error")
+	}
+}
+
+func TestSetField(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		target  interface{}
+		wantErr bool
+	}{
+		{"int", "42", new(int), false},
+		{"int invalid", "abc", new(int), true},
+		{"uint", "42", new(uint), false},
+		{"float", "3.14", new(float64), false},
+		{"bool", "true", new(bool), false},
+		{"bool invalid", "yup", new(bool), true},
+		{"string", "hello", new(string), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fv := reflect.ValueOf(tc.target).Elem()
+			err := setField(fv, tc.raw)
+			if tc.wantErr != (err != nil) {
+				t.Errorf("setField(%q) error = %v, wantErr %v", tc.raw, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetFieldUnsupportedKind(t *testing.T) {
+	target := new(struct{ X struct{ Y int } })
+	fv := reflect.ValueOf(target).Elem().Field(0)
+	err := setField(fv, "1")
+	if err == nil {
+		t.Error("setField() on an unsupported kind = nil error, want error")
+	}
+}
+
+func TestValidateStructRequired(t *testing.T) {
+	type target struct {
+		Name string `binding:"required"`
+	}
+
+	if err := validateStruct(&target{Name: "geektutu"}); err != nil {
+		t.Errorf("validateStruct() with Name set = %v, want nil", err)
+	}
+
+	err := validateStruct(&target{})
+	if err == nil {
+		t.Fatal("validateStruct() with Name unset = nil, want error")
+	}
+	bindErr, ok := err.(*BindError)
+	if !ok {
+		t.Fatalf("validateStruct() error type = %T, want *BindError", err)
+	}
+	if bindErr.Field != "Name" {
+		t.Errorf("BindError.Field = %q, want %q", bindErr.Field, "Name")
+	}
+}
+
+// TestValidateStructUsesBindingTag guards against newValidator() silently
+// reverting to the validator package's default "validate" tag name: a
+// struct tagged only with "binding" must still be enforced.
+func TestValidateStructUsesBindingTag(t *testing.T) {
+	type target struct {
+		Email string `binding:"required,email"`
+	}
+
+	if err := validateStruct(&target{Email: "geektutu@example.com"}); err != nil {
+		t.Errorf("validateStruct() with valid email = %v, want nil", err)
+	}
+
+	err := validateStruct(&target{Email: "not-an-email"})
+	if err == nil {
+		t.Fatal("validateStruct() with invalid email = nil, want error")
+	}
+	bindErr, ok := err.(*BindError)
+	if !ok {
+		t.Fatalf("validateStruct() error type = %T, want *BindError", err)
+	}
+	if bindErr.Field != "Email" {
+		t.Errorf("BindError.Field = %q, want %q", bindErr.Field, "Email")
+	}
+}
@@ -19,9 +19,14 @@ type Context struct {
 	// message
 	Path   string
 	Method string
+	Params map[string]string
 
 	// return
 	StatusCode int
+
+	// middleware
+	handlers []HandlerFunc
+	index    int
 }
 
 func NewContext(w http.ResponseWriter, r *http.Request) *Context {
@@ -30,9 +35,27 @@ func NewContext(w http.ResponseWriter, r *http.Request) *Context {
 		Req:    r,
 		Path:   r.URL.Path,
 		Method: r.Method,
+		index:  -1,
+	}
+}
+
+// Next runs the remaining handlers in the chain. A middleware calls it to
+// yield to the next handler (and, when it returns, to run its own
+// "after" logic), which is what lets Logger/Recovery wrap the request.
+func (c *Context) Next() {
+	c.index++
+	for s := len(c.handlers); c.index < s; c.index++ {
+		c.handlers[c.index](c)
 	}
 }
 
+// Fail aborts the chain with a JSON error response, skipping any
+// remaining handlers.
+func (c *Context) Fail(code int, err string) {
+	c.index = len(c.handlers)
+	c.JSON(code, H{"message": err})
+}
+
 // Get request value
 func (c *Context) GetPostFormValue(key string) string {
 	return c.Req.FormValue(key)
@@ -41,20 +64,26 @@ func (c *Context) GetQueryVal(key string) string {
 	return c.Req.URL.Query().Get(key)
 }
 
+// Param returns the value matched for a ":name" or "*filepath" segment
+// of the route pattern that handled this request.
+func (c *Context) Param(key string) string {
+	return c.Params[key]
+}
+
 // set return value
 func (c *Context) SetStatusCode(code int) {
 	c.StatusCode = code
 	c.Writer.WriteHeader(code)
 }
 func (c *Context) SetHeader(key, value string) {
-	c.Req.Header.Set(key, value)
+	c.Writer.Header().Set(key, value)
 }
 
 // set type of return value
 func (c *Context) String(code int, format string, values ...interface{}) {
 	c.SetHeader(ContentType, "text/plain")
 	c.SetStatusCode(code)
-	if _, err := c.Writer.Write([]byte(fmt.Sprintf(format, values))); err != nil {
+	if _, err := c.Writer.Write([]byte(fmt.Sprintf(format, values...))); err != nil {
 		logger.INFO("set string return type error(context set return value):", err)
 	}
 }
@@ -1,29 +1,79 @@
 package gee
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 type HandlerFunc func(*Context)
 
+// RouterGroup is a prefix plus the Engine it ultimately dispatches
+// through. Groups nest: a group created from another group concatenates
+// its prefix onto the parent's.
+type RouterGroup struct {
+	prefix      string
+	middlewares []HandlerFunc
+	parent      *RouterGroup
+	engine      *Engine
+}
+
+// Engine is itself a RouterGroup (the root one, with an empty prefix) so
+// e.GET(...) keeps working alongside e.Group(...).GET(...).
 type Engine struct {
+	*RouterGroup
 	router *Router
+	groups []*RouterGroup
 }
 
 func New() *Engine {
-	return &Engine{
-		router: NewRouter(),
+	engine := &Engine{router: NewRouter()}
+	engine.RouterGroup = &RouterGroup{engine: engine}
+	engine.groups = []*RouterGroup{engine.RouterGroup}
+	return engine
+}
+
+// Group creates a new RouterGroup nested under g, with prefix appended
+// to g's own prefix.
+func (g *RouterGroup) Group(prefix string) *RouterGroup {
+	engine := g.engine
+	newGroup := &RouterGroup{
+		prefix: g.prefix + prefix,
+		parent: g,
+		engine: engine,
 	}
+	engine.groups = append(engine.groups, newGroup)
+	return newGroup
 }
 
-func (e *Engine) addRoute(method string, pattern string, handler HandlerFunc) {
-	e.router.addRoute(method, pattern, handler)
+// Use appends middleware to g, which then applies to every route in g
+// and its descendant groups.
+func (g *RouterGroup) Use(middlewares ...HandlerFunc) {
+	g.middlewares = append(g.middlewares, middlewares...)
 }
 
-func (e *Engine) GET(pattern string, handler HandlerFunc) {
-	e.router.addRoute("GET", pattern, handler)
+func (g *RouterGroup) addRoute(method string, comp string, handler HandlerFunc) {
+	pattern := g.prefix + comp
+	g.engine.router.addRoute(method, pattern, handler)
 }
 
-func (e *Engine) POST(pattern string, handler HandlerFunc) {
-	e.router.addRoute("POST", pattern, handler)
+func (g *RouterGroup) GET(pattern string, handler HandlerFunc) {
+	g.addRoute("GET", pattern, handler)
+}
+
+func (g *RouterGroup) POST(pattern string, handler HandlerFunc) {
+	g.addRoute("POST", pattern, handler)
+}
+
+func (g *RouterGroup) PUT(pattern string, handler HandlerFunc) {
+	g.addRoute("PUT", pattern, handler)
+}
+
+func (g *RouterGroup) DELETE(pattern string, handler HandlerFunc) {
+	g.addRoute("DELETE", pattern, handler)
+}
+
+func (g *RouterGroup) PATCH(pattern string, handler HandlerFunc) {
+	g.addRoute("PATCH", pattern, handler)
 }
 
 func (e *Engine) Run(addr string) (err error) {
@@ -31,6 +81,13 @@ func (e *Engine) Run(addr string) (err error) {
 }
 
 func (e *Engine) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var middlewares []HandlerFunc
+	for _, group := range e.groups {
+		if strings.HasPrefix(request.URL.Path, group.prefix) {
+			middlewares = append(middlewares, group.middlewares...)
+		}
+	}
 	c := NewContext(writer, request)
+	c.handlers = middlewares
 	e.router.handle(c)
 }
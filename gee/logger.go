@@ -0,0 +1,16 @@
+package gee
+
+import (
+	"logger"
+	"time"
+)
+
+// Logger records method, path, status code and latency for every request
+// that passes through it.
+func Logger() HandlerFunc {
+	return func(c *Context) {
+		start := time.Now()
+		c.Next()
+		logger.INFO("[%d] %s %s in %v", c.StatusCode, c.Method, c.Path, time.Since(start))
+	}
+}
@@ -0,0 +1,40 @@
+package gee
+
+import (
+	"fmt"
+	"logger"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// trace builds a stack trace string for message, skipping the frames of
+// runtime.Callers, trace itself and the deferred function that called it.
+func trace(message string) string {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+
+	var str strings.Builder
+	str.WriteString(message + "\nTraceback:")
+	for _, pc := range pcs[:n] {
+		fn := runtime.FuncForPC(pc)
+		file, line := fn.FileLine(pc)
+		str.WriteString(fmt.Sprintf("\n\t%s:%d", file, line))
+	}
+	return str.String()
+}
+
+// Recovery recovers any panic raised further down the handler chain,
+// logs it with a stack trace, and fails the request with a 500 instead
+// of letting it crash the server.
+func Recovery() HandlerFunc {
+	return func(c *Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				logger.ERROR(trace(fmt.Sprintf("%v", err)))
+				c.Fail(http.StatusInternalServerError, "Internal Server Error")
+			}
+		}()
+		c.Next()
+	}
+}
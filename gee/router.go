@@ -3,29 +3,94 @@ package gee
 import (
 	"logger"
 	"net/http"
+	"strings"
 )
 
+// Router is a per-method trie, so routes registered under different
+// methods (GET "/user/:id" vs POST "/user/:id") don't collide.
 type Router struct {
-	Handlers map[string]HandlerFunc
+	roots    map[string]*node
+	handlers map[string]HandlerFunc
 }
 
 func NewRouter() *Router {
 	return &Router{
-		Handlers: make(map[string]HandlerFunc),
+		roots:    make(map[string]*node),
+		handlers: make(map[string]HandlerFunc),
 	}
 }
 
+// parsePattern splits a pattern into its "/"-separated parts, stopping
+// after the first "*filepath" wildcard since it greedily matches the
+// remainder of the path.
+func parsePattern(pattern string) []string {
+	vs := strings.Split(pattern, "/")
+
+	parts := make([]string, 0)
+	for _, item := range vs {
+		if item == "" {
+			continue
+		}
+		parts = append(parts, item)
+		if item[0] == '*' {
+			break
+		}
+	}
+	return parts
+}
+
 func (r *Router) addRoute(method string, pattern string, handler HandlerFunc) {
 	logger.INFO("[Route]: %4s - %s", method, pattern)
+	parts := parsePattern(pattern)
+
 	key := method + "-" + pattern
-	r.Handlers[key] = handler
+	if _, ok := r.roots[method]; !ok {
+		r.roots[method] = &node{}
+	}
+	r.roots[method].insert(pattern, parts, 0)
+	r.handlers[key] = handler
 }
-func (r *Router) handle(c *Context) {
-	key := c.Method + "-" + c.Path
-	if handler, ok := r.Handlers[key]; ok {
-		handler(c)
-		return
+
+// getRoute resolves path against the trie for method, returning the
+// matched node (whose pattern is the original registered pattern) and
+// the path parameters extracted from any ":name"/"*filepath" parts.
+func (r *Router) getRoute(method string, path string) (*node, map[string]string) {
+	searchParts := parsePattern(path)
+	params := make(map[string]string)
+	root, ok := r.roots[method]
+	if !ok {
+		return nil, nil
 	}
 
-	c.String(http.StatusNotFound, "[Route]: 404 NOT FOUND: %s\n", c.Path)
+	n := root.search(searchParts, 0)
+	if n == nil {
+		return nil, nil
+	}
+
+	parts := parsePattern(n.pattern)
+	for index, part := range parts {
+		if part[0] == ':' {
+			params[part[1:]] = searchParts[index]
+		}
+		if part[0] == '*' && len(part) > 1 {
+			params[part[1:]] = strings.Join(searchParts[index:], "/")
+			break
+		}
+	}
+
+	return n, params
+}
+
+func (r *Router) handle(c *Context) {
+	n, params := r.getRoute(c.Method, c.Path)
+	if n == nil {
+		c.handlers = append(c.handlers, func(c *Context) {
+			c.String(http.StatusNotFound, "[Route]: 404 NOT FOUND: %s\n", c.Path)
+		})
+	} else {
+		c.Params = params
+		key := c.Method + "-" + n.pattern
+		c.handlers = append(c.handlers, r.handlers[key])
+	}
+	c.Next()
 }
@@ -0,0 +1,72 @@
+package gee
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestRouter() *Router {
+	r := NewRouter()
+	r.addRoute("GET", "/", nil)
+	r.addRoute("GET", "/hello/:name", nil)
+	r.addRoute("GET", "/hello/b/c", nil)
+	r.addRoute("GET", "/hi/:name/:sub", nil)
+	r.addRoute("GET", "/assets/*filepath", nil)
+	r.addRoute("POST", "/hello/:name", nil)
+	return r
+}
+
+func TestGetRoute(t *testing.T) {
+	r := newTestRouter()
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		wantMatch  bool
+		wantParams map[string]string
+	}{
+		{"static root", "GET", "/", true, map[string]string{}},
+		{"single param", "GET", "/hello/geektutu", true, map[string]string{"name": "geektutu"}},
+		{"static route under a param prefix", "GET", "/hello/b/c", true, map[string]string{}},
+		{"two params", "GET", "/hi/geektutu/c", true, map[string]string{"name": "geektutu", "sub": "c"}},
+		{"catch-all wildcard", "GET", "/assets/css/style.css", true, map[string]string{"filepath": "css/style.css"}},
+		{"no route for method", "DELETE", "/hello/geektutu", false, nil},
+		{"no route for path", "GET", "/nope", false, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, params := r.getRoute(tc.method, tc.path)
+			if tc.wantMatch && n == nil {
+				t.Fatalf("getRoute(%q, %q) = nil, want a match", tc.method, tc.path)
+			}
+			if !tc.wantMatch {
+				if n != nil {
+					t.Fatalf("getRoute(%q, %q) = %v, want no match", tc.method, tc.path, n)
+				}
+				return
+			}
+			if !reflect.DeepEqual(params, tc.wantParams) {
+				t.Errorf("getRoute(%q, %q) params = %v, want %v", tc.method, tc.path, params, tc.wantParams)
+			}
+		})
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/", []string{}},
+		{"/p/:name", []string{"p", ":name"}},
+		{"/p/*filepath", []string{"p", "*filepath"}},
+		{"/p/*filepath/ignored", []string{"p", "*filepath"}},
+	}
+	for _, tc := range cases {
+		if got := parsePattern(tc.pattern); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parsePattern(%q) = %v, want %v", tc.pattern, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package gee
+
+import (
+	"context"
+	"net/http"
+)
+
+// Server adapts an Engine to the Start()/Stop(ctx) shape app.App expects
+// (see transport.Server), owning the underlying http.Server so Stop can
+// drain in-flight requests via Shutdown instead of killing the listener
+// outright.
+type Server struct {
+	engine *Engine
+	addr   string
+	srv    *http.Server
+}
+
+// Server returns a Server that listens on addr and dispatches to e. The
+// underlying http.Server is built here, not in Start, so Stop can always
+// reach it even if a shutdown signal arrives before Start's goroutine
+// has run.
+func (e *Engine) Server(addr string) *Server {
+	return &Server{engine: e, addr: addr, srv: &http.Server{Addr: addr, Handler: e}}
+}
+
+func (s *Server) Start() error {
+	if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
@@ -0,0 +1,75 @@
+package gee
+
+import "strings"
+
+// node is one segment of a registered pattern in the trie, e.g. the
+// ":id" in "/user/:id/profile". Wildcards (":name" and "*filepath")
+// carry isWild, which makes them match any part. Children are tried in
+// the order they were inserted; a literal child is not preferred over a
+// wildcard sibling.
+type node struct {
+	pattern  string // the full pattern, only set on a terminal node
+	part     string // this segment, e.g. ":id"
+	children []*node
+	isWild   bool
+}
+
+func (n *node) matchChild(part string) *node {
+	for _, child := range n.children {
+		if child.part == part || child.isWild {
+			return child
+		}
+	}
+	return nil
+}
+
+func (n *node) matchChildren(part string) []*node {
+	nodes := make([]*node, 0)
+	for _, child := range n.children {
+		if child.part == part || child.isWild {
+			nodes = append(nodes, child)
+		}
+	}
+	return nodes
+}
+
+// insert registers pattern, walking/creating one node per "/"-separated
+// part. height tracks recursion depth into parts.
+func (n *node) insert(pattern string, parts []string, height int) {
+	if len(parts) == height {
+		n.pattern = pattern
+		return
+	}
+
+	part := parts[height]
+	child := n.matchChild(part)
+	if child == nil {
+		child = &node{part: part, isWild: part[0] == ':' || part[0] == '*'}
+		n.children = append(n.children, child)
+	}
+	child.insert(pattern, parts, height+1)
+}
+
+// search walks the trie for parts, returning the terminal node whose
+// pattern matched, or nil. A "*filepath" part matches the remainder of
+// the path regardless of height.
+func (n *node) search(parts []string, height int) *node {
+	if len(parts) == height || strings.HasPrefix(n.part, "*") {
+		if n.pattern == "" {
+			return nil
+		}
+		return n
+	}
+
+	part := parts[height]
+	children := n.matchChildren(part)
+
+	for _, child := range children {
+		result := child.search(parts, height+1)
+		if result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package app wires one or more transport.Server implementations (the
+// gee HTTP engine, the RPC server, ...) into a single process lifecycle.
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"Prpc/src/transport"
+)
+
+// Hook runs around App.Run: BeforeStart before any server starts,
+// AfterStop once every server has stopped.
+type Hook func() error
+
+// App runs a fixed set of transport.Servers together and shuts all of
+// them down, within ShutdownTimeout, on SIGINT/SIGTERM or a direct Stop
+// call.
+type App struct {
+	Servers         []transport.Server
+	ShutdownTimeout time.Duration
+	BeforeStart     Hook
+	AfterStop       Hook
+
+	cancel context.CancelFunc
+}
+
+// New builds an App around servers, with a 5s default ShutdownTimeout.
+func New(servers ...transport.Server) *App {
+	return &App{Servers: servers, ShutdownTimeout: 5 * time.Second}
+}
+
+// Run starts every server concurrently and blocks until one of them
+// fails, the process receives SIGINT/SIGTERM, or Stop is called
+// directly - then stops all of them and returns the first error, if any.
+func (a *App) Run() error {
+	if a.BeforeStart != nil {
+		if err := a.BeforeStart(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	defer cancel()
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, s := range a.Servers {
+		s := s
+		g.Go(s.Start)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	g.Go(func() error {
+		select {
+		case sig := <-sigCh:
+			log.Println("app: received signal", sig, "- shutting down")
+		case <-gctx.Done():
+		}
+		return a.Stop()
+	})
+
+	err := g.Wait()
+	if a.AfterStop != nil {
+		if hookErr := a.AfterStop(); hookErr != nil && err == nil {
+			err = hookErr
+		}
+	}
+	return err
+}
+
+// Stop shuts down every server within ShutdownTimeout. Run calls this
+// automatically on signal; call it directly to stop the app
+// programmatically.
+func (a *App) Stop() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.ShutdownTimeout)
+	defer cancel()
+
+	var firstErr error
+	for _, s := range a.Servers {
+		if err := s.Stop(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
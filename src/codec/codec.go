@@ -4,12 +4,36 @@ import (
 	"io"
 )
 
+// MessageType distinguishes the kind of frame a Header introduces. Unary
+// calls only ever use Request/Response; streaming calls additionally use
+// StreamOpen/StreamMsg/StreamClose, all multiplexed over one connection
+// by Seq.
+type MessageType uint8
+
+const (
+	Request MessageType = iota
+	Response
+	StreamOpen
+	StreamMsg
+	StreamClose
+	Error
+)
+
+// Header is the fixed part of every frame exchanged between client and
+// server. The body (request args, reply, or stream message) follows and
+// is encoded separately by the negotiated Codec.
 type Header struct {
 	ServiceMethod string // Service.Method
-	Seq           uint64 // From client
-	Error         string
+	Seq           uint64 // call/stream identifier, set by the client
+	Error         string // non-empty on a failed Response or an Error frame
+	Type          MessageType
 }
 
+// Codec abstracts the wire encoding used for a single connection. A Codec
+// is responsible for its own message framing: implementations whose
+// underlying encoding is not self-delimiting (JSON, Protobuf, MessagePack)
+// must length-prefix each value they write so ReadHeader/ReadBody can find
+// the message boundaries again.
 type Codec interface {
 	io.Closer
 	ReadHeader(*Header) error
@@ -17,8 +41,40 @@ type Codec interface {
 	Write(*Header, interface{}) error
 }
 
+// NewCodecFunc builds a Codec around an already-established connection.
 type NewCodecFunc func(io.ReadWriteCloser) Codec
 
+// Type identifies a wire encoding, exchanged during the connection
+// handshake so client and server agree on which Codec to use.
 type Type string
 
-const ()
+const (
+	GobType      Type = "application/gob"
+	JsonType     Type = "application/json"
+	ProtobufType Type = "application/protobuf"
+	MsgpackType  Type = "application/msgpack"
+)
+
+// newCodecFuncMap is the global codec registry. The built-in codecs are
+// registered by init below; callers can Register additional ones (e.g. a custom
+// Type) before the first connection is accepted or dialed.
+var newCodecFuncMap map[Type]NewCodecFunc
+
+func init() {
+	newCodecFuncMap = make(map[Type]NewCodecFunc)
+	newCodecFuncMap[GobType] = NewGobCodec
+	newCodecFuncMap[JsonType] = NewJsonCodec
+	newCodecFuncMap[ProtobufType] = NewProtobufCodec
+	newCodecFuncMap[MsgpackType] = NewMsgpackCodec
+}
+
+// Register adds or overrides the NewCodecFunc used for typ.
+func Register(typ Type, f NewCodecFunc) {
+	newCodecFuncMap[typ] = f
+}
+
+// Get returns the NewCodecFunc registered for typ, or nil if none is
+// registered.
+func Get(typ Type) NewCodecFunc {
+	return newCodecFuncMap[typ]
+}
@@ -0,0 +1,42 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// maxFrameSize guards readFrame against a corrupt or malicious length
+// prefix forcing an oversized allocation.
+const maxFrameSize = 64 << 20 // 64MiB
+
+// writeFrame writes data as a single length-prefixed frame: a 4-byte
+// big-endian length followed by data itself. It is used by codecs whose
+// encoding is not self-delimiting (JSON, Protobuf, MessagePack) so
+// ReadHeader/ReadBody can recover message boundaries on a raw stream.
+func writeFrame(w io.Writer, data []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readFrame reads back one frame written by writeFrame.
+func readFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxFrameSize {
+		return nil, errors.New("codec: frame exceeds max size")
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -4,20 +4,56 @@ import (
 	"bufio"
 	"encoding/gob"
 	"io"
+	"log"
 )
 
+// GobCodec encodes Header/body pairs with encoding/gob, which is
+// self-delimiting, so no extra framing is needed on top of it.
 type GobCodec struct {
 	conn io.ReadWriteCloser
-	dec  *gob.GobDecoder
-	enc  *gob.GobEncoder
 	buf  *bufio.Writer
+	dec  *gob.Decoder
+	enc  *gob.Encoder
 }
 
 var _ Codec = (*GobCodec)(nil)
 
-func NewGoCodec(conn io.ReadWriteCloser) Codec {
+func NewGobCodec(conn io.ReadWriteCloser) Codec {
 	buf := bufio.NewWriter(conn)
 	return &GobCodec{
 		conn: conn,
+		buf:  buf,
+		dec:  gob.NewDecoder(conn),
+		enc:  gob.NewEncoder(buf),
 	}
 }
+
+func (c *GobCodec) ReadHeader(h *Header) error {
+	return c.dec.Decode(h)
+}
+
+func (c *GobCodec) ReadBody(body interface{}) error {
+	return c.dec.Decode(body)
+}
+
+func (c *GobCodec) Write(h *Header, body interface{}) (err error) {
+	defer func() {
+		_ = c.buf.Flush()
+		if err != nil {
+			_ = c.Close()
+		}
+	}()
+	if err = c.enc.Encode(h); err != nil {
+		log.Println("rpc codec: gob error encoding header:", err)
+		return
+	}
+	if err = c.enc.Encode(body); err != nil {
+		log.Println("rpc codec: gob error encoding body:", err)
+		return
+	}
+	return
+}
+
+func (c *GobCodec) Close() error {
+	return c.conn.Close()
+}
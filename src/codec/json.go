@@ -0,0 +1,78 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+)
+
+// JsonCodec encodes Header/body pairs as JSON. JSON values are not
+// self-delimiting on a raw stream, so each one is wrapped in a
+// length-prefixed frame (see writeFrame/readFrame).
+type JsonCodec struct {
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+var _ Codec = (*JsonCodec)(nil)
+
+func NewJsonCodec(conn io.ReadWriteCloser) Codec {
+	return &JsonCodec{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+}
+
+func (c *JsonCodec) ReadHeader(h *Header) error {
+	data, err := readFrame(c.reader)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, h)
+}
+
+func (c *JsonCodec) ReadBody(body interface{}) error {
+	data, err := readFrame(c.reader)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		return nil
+	}
+	return json.Unmarshal(data, body)
+}
+
+func (c *JsonCodec) Write(h *Header, body interface{}) (err error) {
+	defer func() {
+		_ = c.writer.Flush()
+		if err != nil {
+			_ = c.Close()
+		}
+	}()
+	headerData, err := json.Marshal(h)
+	if err != nil {
+		log.Println("rpc codec: json error encoding header:", err)
+		return
+	}
+	if err = writeFrame(c.writer, headerData); err != nil {
+		log.Println("rpc codec: json error writing header:", err)
+		return
+	}
+	bodyData, err := json.Marshal(body)
+	if err != nil {
+		log.Println("rpc codec: json error encoding body:", err)
+		return
+	}
+	if err = writeFrame(c.writer, bodyData); err != nil {
+		log.Println("rpc codec: json error writing body:", err)
+		return
+	}
+	return
+}
+
+func (c *JsonCodec) Close() error {
+	return c.conn.Close()
+}
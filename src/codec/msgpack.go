@@ -0,0 +1,79 @@
+package codec
+
+import (
+	"bufio"
+	"io"
+	"log"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackCodec encodes Header/body pairs as MessagePack. Like JSON and
+// Protobuf, MessagePack values are not self-delimiting on a raw stream,
+// so each one is wrapped in a length-prefixed frame.
+type MsgpackCodec struct {
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+var _ Codec = (*MsgpackCodec)(nil)
+
+func NewMsgpackCodec(conn io.ReadWriteCloser) Codec {
+	return &MsgpackCodec{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+}
+
+func (c *MsgpackCodec) ReadHeader(h *Header) error {
+	data, err := readFrame(c.reader)
+	if err != nil {
+		return err
+	}
+	return msgpack.Unmarshal(data, h)
+}
+
+func (c *MsgpackCodec) ReadBody(body interface{}) error {
+	data, err := readFrame(c.reader)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		return nil
+	}
+	return msgpack.Unmarshal(data, body)
+}
+
+func (c *MsgpackCodec) Write(h *Header, body interface{}) (err error) {
+	defer func() {
+		_ = c.writer.Flush()
+		if err != nil {
+			_ = c.Close()
+		}
+	}()
+	headerData, err := msgpack.Marshal(h)
+	if err != nil {
+		log.Println("rpc codec: msgpack error encoding header:", err)
+		return
+	}
+	if err = writeFrame(c.writer, headerData); err != nil {
+		log.Println("rpc codec: msgpack error writing header:", err)
+		return
+	}
+	bodyData, err := msgpack.Marshal(body)
+	if err != nil {
+		log.Println("rpc codec: msgpack error encoding body:", err)
+		return
+	}
+	if err = writeFrame(c.writer, bodyData); err != nil {
+		log.Println("rpc codec: msgpack error writing body:", err)
+		return
+	}
+	return
+}
+
+func (c *MsgpackCodec) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,109 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufCodec encodes the body as protobuf and the Header as JSON (a
+// Header carries no proto definition of its own). Neither is
+// self-delimiting, so both are length-prefixed frames.
+type ProtobufCodec struct {
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+var _ Codec = (*ProtobufCodec)(nil)
+
+func NewProtobufCodec(conn io.ReadWriteCloser) Codec {
+	return &ProtobufCodec{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+}
+
+func (c *ProtobufCodec) ReadHeader(h *Header) error {
+	data, err := readFrame(c.reader)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, h)
+}
+
+func (c *ProtobufCodec) ReadBody(body interface{}) error {
+	data, err := readFrame(c.reader)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		return nil
+	}
+	msg, ok := body.(proto.Message)
+	if !ok {
+		// Framework-internal bodies (the server's invalidRequest
+		// sentinel, rpc.StreamFrame for streaming) have no proto
+		// definition; they're framed as JSON instead, both here and in
+		// Write.
+		if len(data) == 0 {
+			return nil
+		}
+		return json.Unmarshal(data, body)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func (c *ProtobufCodec) Write(h *Header, body interface{}) (err error) {
+	defer func() {
+		_ = c.writer.Flush()
+		if err != nil {
+			_ = c.Close()
+		}
+	}()
+	headerData, err := json.Marshal(h)
+	if err != nil {
+		log.Println("rpc codec: protobuf error encoding header:", err)
+		return
+	}
+	if err = writeFrame(c.writer, headerData); err != nil {
+		log.Println("rpc codec: protobuf error writing header:", err)
+		return
+	}
+	msg, ok := body.(proto.Message)
+	if !ok {
+		// Framework-internal bodies (the server's invalidRequest
+		// sentinel, rpc.StreamFrame for streaming) have no proto
+		// definition; fall back to JSON instead of failing the whole
+		// connection over a body type that was never meant to cross the
+		// wire as protobuf.
+		bodyData, jsonErr := json.Marshal(body)
+		if jsonErr != nil {
+			err = jsonErr
+			log.Println("rpc codec: protobuf error encoding body:", err)
+			return
+		}
+		if err = writeFrame(c.writer, bodyData); err != nil {
+			log.Println("rpc codec: protobuf error writing body:", err)
+		}
+		return
+	}
+	bodyData, err := proto.Marshal(msg)
+	if err != nil {
+		log.Println("rpc codec: protobuf error encoding body:", err)
+		return
+	}
+	if err = writeFrame(c.writer, bodyData); err != nil {
+		log.Println("rpc codec: protobuf error writing body:", err)
+		return
+	}
+	return
+}
+
+func (c *ProtobufCodec) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// consulTTL is the TTL given to each registered service's health check.
+// renewals must land well inside this window or Consul marks the check
+// (and, after DeregisterCriticalServiceAfter, the whole service) critical.
+const consulTTL = 15 * time.Second
+
+// ConsulRegistry registers services with a Consul agent. Unlike
+// EtcdRegistry's lease, Consul's agent doesn't renew the TTL check on its
+// own, so Register starts a goroutine that calls UpdateTTL on an interval
+// well under consulTTL; Deregister stops it.
+type ConsulRegistry struct {
+	client *consulapi.Client
+
+	mu    sync.Mutex
+	stops map[string]chan struct{}
+}
+
+func NewConsulRegistry(addr string) (*ConsulRegistry, error) {
+	cfg := consulapi.DefaultConfig()
+	cfg.Address = addr
+	client, err := consulapi.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsulRegistry{client: client, stops: make(map[string]chan struct{})}, nil
+}
+
+var _ Registry = (*ConsulRegistry)(nil)
+
+func serviceID(svc *Service) string {
+	return svc.Name + "-" + svc.Addr
+}
+
+func splitHostPort(addr string) (host string, port int, err error) {
+	idx := strings.LastIndex(addr, ":")
+	if idx < 0 {
+		return "", 0, strconv.ErrSyntax
+	}
+	port, err = strconv.Atoi(addr[idx+1:])
+	return addr[:idx], port, err
+}
+
+func (r *ConsulRegistry) Register(svc *Service) error {
+	host, port, err := splitHostPort(svc.Addr)
+	if err != nil {
+		return err
+	}
+	reg := &consulapi.AgentServiceRegistration{
+		ID:      serviceID(svc),
+		Name:    svc.Name,
+		Address: host,
+		Port:    port,
+		Tags:    svc.Methods,
+		Check: &consulapi.AgentServiceCheck{
+			TTL:                            consulTTL.String(),
+			DeregisterCriticalServiceAfter: "1m",
+		},
+	}
+	if err := r.client.Agent().ServiceRegister(reg); err != nil {
+		return err
+	}
+	checkID := "service:" + serviceID(svc)
+	if err := r.client.Agent().UpdateTTL(checkID, "", consulapi.HealthPassing); err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	r.mu.Lock()
+	r.stops[serviceID(svc)] = stop
+	r.mu.Unlock()
+
+	go r.renew(checkID, stop)
+	return nil
+}
+
+// renew keeps svc's check passing by calling UpdateTTL well inside
+// consulTTL, mirroring how EtcdRegistry's KeepAlive goroutine keeps a
+// lease alive for as long as the service is registered.
+func (r *ConsulRegistry) renew(checkID string, stop chan struct{}) {
+	ticker := time.NewTicker(consulTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.client.Agent().UpdateTTL(checkID, "", consulapi.HealthPassing); err != nil {
+				log.Println("registry: consul TTL renewal error:", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (r *ConsulRegistry) Deregister(svc *Service) error {
+	r.mu.Lock()
+	if stop, ok := r.stops[serviceID(svc)]; ok {
+		close(stop)
+		delete(r.stops, serviceID(svc))
+	}
+	r.mu.Unlock()
+	return r.client.Agent().ServiceDeregister(serviceID(svc))
+}
+
+func (r *ConsulRegistry) GetService(name string) ([]*Service, error) {
+	entries, _, err := r.client.Health().Service(name, "", true, nil)
+	if err != nil {
+		return nil, err
+	}
+	svcs := make([]*Service, 0, len(entries))
+	for _, e := range entries {
+		svcs = append(svcs, &Service{
+			Name:    e.Service.Service,
+			Addr:    e.Service.Address + ":" + strconv.Itoa(e.Service.Port),
+			Methods: e.Service.Tags,
+		})
+	}
+	return svcs, nil
+}
+
+// Watch polls Consul's blocking-query endpoint and diffs successive
+// results into add/delete Events, since the Consul client doesn't expose
+// a push subscription the way etcd's Watch does.
+func (r *ConsulRegistry) Watch(name string) (Watcher, error) {
+	w := &consulWatcher{client: r.client, name: name, events: make(chan *Event, 16), stop: make(chan struct{})}
+	go w.run()
+	return w, nil
+}
+
+type consulWatcher struct {
+	client *consulapi.Client
+	name   string
+	events chan *Event
+	stop   chan struct{}
+}
+
+func (w *consulWatcher) run() {
+	var lastIndex uint64
+	seen := make(map[string]*Service)
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		entries, meta, err := w.client.Health().Service(w.name, "", true, &consulapi.QueryOptions{
+			WaitIndex: lastIndex,
+			WaitTime:  30 * time.Second,
+		})
+		if err != nil {
+			time.Sleep(time.Second)
+			continue
+		}
+		lastIndex = meta.LastIndex
+
+		current := make(map[string]*Service, len(entries))
+		for _, e := range entries {
+			svc := &Service{
+				Name:    e.Service.Service,
+				Addr:    e.Service.Address + ":" + strconv.Itoa(e.Service.Port),
+				Methods: e.Service.Tags,
+			}
+			current[svc.Addr] = svc
+		}
+		for addr, svc := range current {
+			if _, ok := seen[addr]; !ok {
+				w.events <- &Event{Type: EventAdd, Service: svc}
+			}
+		}
+		for addr, svc := range seen {
+			if _, ok := current[addr]; !ok {
+				w.events <- &Event{Type: EventDelete, Service: svc}
+			}
+		}
+		seen = current
+	}
+}
+
+func (w *consulWatcher) Next() (*Event, error) {
+	select {
+	case e := <-w.events:
+		return e, nil
+	case <-w.stop:
+		return nil, ErrWatcherStopped
+	}
+}
+
+func (w *consulWatcher) Stop() {
+	close(w.stop)
+}
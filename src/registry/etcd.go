@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const etcdPrefix = "/prpc/registry/"
+
+// EtcdRegistry stores each Service as a lease-backed key under
+// etcdPrefix, so a process that crashes without deregistering expires
+// out of the registry instead of lingering forever.
+type EtcdRegistry struct {
+	client *clientv3.Client
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	leases map[string]clientv3.LeaseID
+}
+
+func NewEtcdRegistry(endpoints []string, ttl time.Duration) (*EtcdRegistry, error) {
+	cli, err := clientv3.New(clientv3.Config{Endpoints: endpoints, DialTimeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &EtcdRegistry{client: cli, ttl: ttl, leases: make(map[string]clientv3.LeaseID)}, nil
+}
+
+var _ Registry = (*EtcdRegistry)(nil)
+
+func (r *EtcdRegistry) key(svc *Service) string {
+	return fmt.Sprintf("%s%s/%s", etcdPrefix, svc.Name, svc.Addr)
+}
+
+func (r *EtcdRegistry) Register(svc *Service) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lease, err := r.client.Grant(ctx, int64(r.ttl.Seconds()))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return err
+	}
+	if _, err = r.client.Put(ctx, r.key(svc), string(data), clientv3.WithLease(lease.ID)); err != nil {
+		return err
+	}
+	keepAlive, err := r.client.KeepAlive(context.Background(), lease.ID)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for range keepAlive {
+			// drain; etcd renews the lease as long as this channel is read
+		}
+	}()
+
+	r.mu.Lock()
+	r.leases[r.key(svc)] = lease.ID
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *EtcdRegistry) Deregister(svc *Service) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.client.Delete(ctx, r.key(svc))
+
+	r.mu.Lock()
+	delete(r.leases, r.key(svc))
+	r.mu.Unlock()
+	return err
+}
+
+func (r *EtcdRegistry) GetService(name string) ([]*Service, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := r.client.Get(ctx, etcdPrefix+name+"/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	svcs := make([]*Service, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var svc Service
+		if err := json.Unmarshal(kv.Value, &svc); err != nil {
+			continue
+		}
+		svcs = append(svcs, &svc)
+	}
+	return svcs, nil
+}
+
+func (r *EtcdRegistry) Watch(name string) (Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := r.client.Watch(ctx, etcdPrefix+name+"/", clientv3.WithPrefix(), clientv3.WithPrevKV())
+	return &etcdWatcher{cancel: cancel, ch: ch}, nil
+}
+
+type etcdWatcher struct {
+	cancel context.CancelFunc
+	ch     clientv3.WatchChan
+}
+
+func (w *etcdWatcher) Next() (*Event, error) {
+	for resp := range w.ch {
+		for _, ev := range resp.Events {
+			var svc Service
+			if ev.Type == clientv3.EventTypeDelete {
+				_ = json.Unmarshal(ev.PrevKv.GetValue(), &svc)
+				return &Event{Type: EventDelete, Service: &svc}, nil
+			}
+			if err := json.Unmarshal(ev.Kv.Value, &svc); err != nil {
+				continue
+			}
+			return &Event{Type: EventAdd, Service: &svc}, nil
+		}
+	}
+	return nil, ErrWatcherStopped
+}
+
+func (w *etcdWatcher) Stop() {
+	w.cancel()
+}
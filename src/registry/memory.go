@@ -0,0 +1,94 @@
+package registry
+
+import "sync"
+
+// MemoryRegistry is an in-process Registry with no external dependency,
+// useful for tests and for running client and server in the same
+// binary.
+type MemoryRegistry struct {
+	mu       sync.RWMutex
+	services map[string]map[string]*Service // name -> addr -> Service
+	watchers map[string][]*memoryWatcher
+}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		services: make(map[string]map[string]*Service),
+		watchers: make(map[string][]*memoryWatcher),
+	}
+}
+
+var _ Registry = (*MemoryRegistry)(nil)
+
+func (r *MemoryRegistry) Register(svc *Service) error {
+	r.mu.Lock()
+	if r.services[svc.Name] == nil {
+		r.services[svc.Name] = make(map[string]*Service)
+	}
+	r.services[svc.Name][svc.Addr] = svc
+	watchers := append([]*memoryWatcher(nil), r.watchers[svc.Name]...)
+	r.mu.Unlock()
+
+	for _, w := range watchers {
+		w.send(&Event{Type: EventAdd, Service: svc})
+	}
+	return nil
+}
+
+func (r *MemoryRegistry) Deregister(svc *Service) error {
+	r.mu.Lock()
+	if addrs, ok := r.services[svc.Name]; ok {
+		delete(addrs, svc.Addr)
+	}
+	watchers := append([]*memoryWatcher(nil), r.watchers[svc.Name]...)
+	r.mu.Unlock()
+
+	for _, w := range watchers {
+		w.send(&Event{Type: EventDelete, Service: svc})
+	}
+	return nil
+}
+
+func (r *MemoryRegistry) GetService(name string) ([]*Service, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	svcs := make([]*Service, 0, len(r.services[name]))
+	for _, svc := range r.services[name] {
+		svcs = append(svcs, svc)
+	}
+	return svcs, nil
+}
+
+func (r *MemoryRegistry) Watch(name string) (Watcher, error) {
+	w := &memoryWatcher{events: make(chan *Event, 16), stop: make(chan struct{})}
+	r.mu.Lock()
+	r.watchers[name] = append(r.watchers[name], w)
+	r.mu.Unlock()
+	return w, nil
+}
+
+type memoryWatcher struct {
+	events chan *Event
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func (w *memoryWatcher) send(e *Event) {
+	select {
+	case w.events <- e:
+	case <-w.stop:
+	}
+}
+
+func (w *memoryWatcher) Next() (*Event, error) {
+	select {
+	case e := <-w.events:
+		return e, nil
+	case <-w.stop:
+		return nil, ErrWatcherStopped
+	}
+}
+
+func (w *memoryWatcher) Stop() {
+	w.once.Do(func() { close(w.stop) })
+}
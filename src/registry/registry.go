@@ -0,0 +1,47 @@
+package registry
+
+import "errors"
+
+// Service is one instance of a named RPC service advertised in the
+// registry: an address plus the methods it exposes.
+type Service struct {
+	Name    string
+	Addr    string
+	Methods []string
+}
+
+// EventType distinguishes a service instance coming online from one
+// going away.
+type EventType int
+
+const (
+	EventAdd EventType = iota
+	EventDelete
+)
+
+// Event is one add/delete notification delivered by a Watcher.
+type Event struct {
+	Type    EventType
+	Service *Service
+}
+
+// Watcher streams Events for the service a Watch call subscribed to,
+// until Stop is called, at which point a blocked Next returns
+// ErrWatcherStopped.
+type Watcher interface {
+	Next() (*Event, error)
+	Stop()
+}
+
+// Registry is the contract every discovery backend (in-memory, etcd,
+// Consul, ...) implements.
+type Registry interface {
+	Register(svc *Service) error
+	Deregister(svc *Service) error
+	GetService(name string) ([]*Service, error)
+	Watch(name string) (Watcher, error)
+}
+
+// ErrWatcherStopped is returned by Watcher.Next once Stop has been
+// called.
+var ErrWatcherStopped = errors.New("registry: watcher stopped")
@@ -0,0 +1,271 @@
+package rpc
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+
+	"Prpc/src/codec"
+)
+
+// Call represents an active RPC, completing asynchronously.
+type Call struct {
+	Seq           uint64
+	ServiceMethod string
+	Args          interface{}
+	Reply         interface{}
+	Error         error
+	Done          chan *Call
+}
+
+func (c *Call) done() {
+	c.Done <- c
+}
+
+// Client is one connection to an RPC server, already past the handshake
+// and able to dispatch concurrent calls over it.
+type Client struct {
+	cc      codec.Codec
+	opt     *Option
+	sending sync.Mutex // guards writes to cc, one Header/body at a time
+
+	mu       sync.Mutex
+	seq      uint64
+	pending  map[uint64]*Call         // in-flight unary calls, keyed by Seq
+	streams  map[uint64]*ClientStream // open streams, keyed by Seq
+	closing  bool                     // Close was called by the user
+	shutdown bool                     // server told us to stop, or we hit an unrecoverable error
+}
+
+var _ io.Closer = (*Client)(nil)
+
+var ErrShutdown = errors.New("rpc client: connection is shut down")
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return ErrShutdown
+	}
+	c.closing = true
+	return c.cc.Close()
+}
+
+func (c *Client) IsAvailable() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.shutdown && !c.closing
+}
+
+func (c *Client) registerCall(call *Call) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing || c.shutdown {
+		return 0, ErrShutdown
+	}
+	call.Seq = c.seq
+	c.pending[call.Seq] = call
+	c.seq++
+	return call.Seq, nil
+}
+
+func (c *Client) removeCall(seq uint64) *Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call := c.pending[seq]
+	delete(c.pending, seq)
+	return call
+}
+
+func (c *Client) removeStream(seq uint64) {
+	c.mu.Lock()
+	delete(c.streams, seq)
+	c.mu.Unlock()
+}
+
+func (c *Client) terminateCalls(err error) {
+	c.sending.Lock()
+	defer c.sending.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shutdown = true
+	for _, call := range c.pending {
+		call.Error = err
+		call.done()
+	}
+	for _, stream := range c.streams {
+		stream.deliver(nil, err)
+	}
+}
+
+// receive reads Header/body pairs off the connection until it fails,
+// dispatching each to whichever Call or ClientStream is waiting for it,
+// keyed by Header.Seq.
+func (c *Client) receive() {
+	var err error
+	for err == nil {
+		var h codec.Header
+		if err = c.cc.ReadHeader(&h); err != nil {
+			break
+		}
+
+		switch h.Type {
+		case codec.StreamMsg, codec.StreamClose, codec.Error:
+			var sf StreamFrame
+			bodyErr := c.cc.ReadBody(&sf)
+
+			c.mu.Lock()
+			stream := c.streams[h.Seq]
+			c.mu.Unlock()
+			if stream == nil {
+				continue
+			}
+			if bodyErr != nil {
+				stream.deliver(nil, bodyErr)
+				continue
+			}
+			switch h.Type {
+			case codec.StreamMsg:
+				stream.deliver(&sf, nil)
+			case codec.StreamClose:
+				stream.deliver(nil, io.EOF)
+				c.removeStream(h.Seq)
+			case codec.Error:
+				stream.deliver(nil, errors.New(h.Error))
+				c.removeStream(h.Seq)
+			}
+
+		default: // Response
+			call := c.removeCall(h.Seq)
+			switch {
+			case call == nil:
+				// Write partially failed and the call was already removed.
+				err = c.cc.ReadBody(nil)
+			case h.Error != "":
+				call.Error = errors.New(h.Error)
+				err = c.cc.ReadBody(nil)
+				call.done()
+			default:
+				err = c.cc.ReadBody(call.Reply)
+				if err != nil {
+					call.Error = errors.New("rpc client: reading body " + err.Error())
+				}
+				call.done()
+			}
+		}
+	}
+	c.terminateCalls(err)
+}
+
+func NewClient(conn net.Conn, opt *Option) (*Client, error) {
+	f := codec.Get(opt.CodecType)
+	if f == nil {
+		return nil, fmt.Errorf("rpc client: invalid codec type %s", opt.CodecType)
+	}
+	if err := gob.NewEncoder(conn).Encode(opt); err != nil {
+		log.Println("rpc client: options error:", err)
+		_ = conn.Close()
+		return nil, err
+	}
+	return newClientCodec(f(conn), opt), nil
+}
+
+func newClientCodec(cc codec.Codec, opt *Option) *Client {
+	client := &Client{
+		seq:     1,
+		cc:      cc,
+		opt:     opt,
+		pending: make(map[uint64]*Call),
+		streams: make(map[uint64]*ClientStream),
+	}
+	go client.receive()
+	return client
+}
+
+func parseOptions(opts ...*Option) (*Option, error) {
+	if len(opts) == 0 || opts[0] == nil {
+		return DefaultOption, nil
+	}
+	if len(opts) != 1 {
+		return nil, errors.New("rpc client: too many options")
+	}
+	opt := opts[0]
+	opt.MagicNumber = DefaultOption.MagicNumber
+	if opt.CodecType == "" {
+		opt.CodecType = DefaultOption.CodecType
+	}
+	return opt, nil
+}
+
+// Dial connects to an RPC server at the given network address,
+// negotiating the codec named in opts (or DefaultOption's if none is
+// given).
+func Dial(network, address string, opts ...*Option) (client *Client, err error) {
+	opt, err := parseOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if client == nil {
+			_ = conn.Close()
+		}
+	}()
+	return NewClient(conn, opt)
+}
+
+func (c *Client) send(call *Call) {
+	c.sending.Lock()
+	defer c.sending.Unlock()
+
+	seq, err := c.registerCall(call)
+	if err != nil {
+		call.Error = err
+		call.done()
+		return
+	}
+
+	h := &codec.Header{
+		ServiceMethod: call.ServiceMethod,
+		Seq:           seq,
+		Type:          codec.Request,
+	}
+	if err := c.cc.Write(h, call.Args); err != nil {
+		call := c.removeCall(seq)
+		if call != nil {
+			call.Error = err
+			call.done()
+		}
+	}
+}
+
+// Go invokes the function asynchronously, returning the Call that will
+// carry the result on its Done channel.
+func (c *Client) Go(serviceMethod string, args, reply interface{}, done chan *Call) *Call {
+	if done == nil {
+		done = make(chan *Call, 10)
+	} else if cap(done) == 0 {
+		log.Panic("rpc client: done channel is unbuffered")
+	}
+	call := &Call{
+		ServiceMethod: serviceMethod,
+		Args:          args,
+		Reply:         reply,
+		Done:          done,
+	}
+	c.send(call)
+	return call
+}
+
+// Call invokes the named function and blocks until it completes.
+func (c *Client) Call(serviceMethod string, args, reply interface{}) error {
+	call := <-c.Go(serviceMethod, args, reply, make(chan *Call, 1)).Done
+	return call.Error
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"Prpc/src/codec"
+)
+
+// ClientStream is the client-side handle returned by Client.Stream. Like
+// the server's Stream, it shares the underlying connection with every
+// other call and stream on it, multiplexed by Seq.
+type ClientStream struct {
+	seq           uint64
+	serviceMethod string
+	client        *Client
+
+	queue *frameQueue
+
+	closeSendOnce sync.Once
+}
+
+// deliver is called by Client.receive, the connection's single reader,
+// and must never block on a slow Recv caller - see frameQueue.
+func (cs *ClientStream) deliver(frame *StreamFrame, err error) {
+	cs.queue.push(frame, err)
+}
+
+// Stream opens a streaming call to serviceMethod, which must be
+// registered server-side as func(ctx, *Stream) error. ctx is accepted
+// for symmetry with the server-side signature but the client doesn't yet
+// act on cancellation beyond what CloseSend/Close already do.
+func (c *Client) Stream(ctx context.Context, serviceMethod string) (*ClientStream, error) {
+	c.mu.Lock()
+	if c.closing || c.shutdown {
+		c.mu.Unlock()
+		return nil, ErrShutdown
+	}
+	seq := c.seq
+	c.seq++
+	cs := &ClientStream{
+		seq:           seq,
+		serviceMethod: serviceMethod,
+		client:        c,
+		queue:         newFrameQueue(),
+	}
+	c.streams[seq] = cs
+	c.mu.Unlock()
+
+	c.sending.Lock()
+	h := &codec.Header{ServiceMethod: serviceMethod, Seq: seq, Type: codec.StreamOpen}
+	err := c.cc.Write(h, &StreamFrame{})
+	c.sending.Unlock()
+	if err != nil {
+		c.removeStream(seq)
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Send marshals v as JSON and writes it as a StreamMsg frame.
+func (cs *ClientStream) Send(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	cs.client.sending.Lock()
+	defer cs.client.sending.Unlock()
+	h := &codec.Header{ServiceMethod: cs.serviceMethod, Seq: cs.seq, Type: codec.StreamMsg}
+	return cs.client.cc.Write(h, &StreamFrame{Payload: data})
+}
+
+// Recv blocks for the next message sent by the server, unmarshaling its
+// payload into v. It returns io.EOF once the server closes the stream.
+func (cs *ClientStream) Recv(v interface{}) error {
+	frame, err := cs.queue.pop()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(frame.Payload, v)
+}
+
+// CloseSend tells the server this side has no more messages to send. It
+// is safe to call more than once; only the first call has any effect.
+func (cs *ClientStream) CloseSend() error {
+	var err error
+	cs.closeSendOnce.Do(func() {
+		cs.client.sending.Lock()
+		defer cs.client.sending.Unlock()
+		h := &codec.Header{ServiceMethod: cs.serviceMethod, Seq: cs.seq, Type: codec.StreamClose}
+		err = cs.client.cc.Write(h, &StreamFrame{})
+	})
+	return err
+}
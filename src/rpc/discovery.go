@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"errors"
+	"sync"
+
+	"Prpc/src/registry"
+)
+
+// DiscoveryClient resolves serviceName's instances through reg and
+// load-balances calls across them with selector, refreshing its pool as
+// reg's Watcher reports instances coming and going so long-lived clients
+// never have to poll.
+type DiscoveryClient struct {
+	reg      registry.Registry
+	service  string
+	selector Selector
+	opt      *Option
+	watcher  registry.Watcher
+
+	mu      sync.Mutex
+	servers []string
+	clients map[string]*Client
+}
+
+func NewDiscoveryClient(reg registry.Registry, serviceName string, selector Selector, opts ...*Option) (*DiscoveryClient, error) {
+	opt, err := parseOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	svcs, err := reg.GetService(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := reg.Watch(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := &DiscoveryClient{
+		reg:      reg,
+		service:  serviceName,
+		selector: selector,
+		opt:      opt,
+		watcher:  watcher,
+		clients:  make(map[string]*Client),
+	}
+	for _, svc := range svcs {
+		dc.servers = append(dc.servers, svc.Addr)
+	}
+	go dc.watch()
+	return dc, nil
+}
+
+func (dc *DiscoveryClient) watch() {
+	for {
+		event, err := dc.watcher.Next()
+		if err != nil {
+			return // registry.ErrWatcherStopped, or a backend-specific failure
+		}
+		dc.mu.Lock()
+		switch event.Type {
+		case registry.EventAdd:
+			if !contains(dc.servers, event.Service.Addr) {
+				dc.servers = append(dc.servers, event.Service.Addr)
+			}
+		case registry.EventDelete:
+			dc.servers = remove(dc.servers, event.Service.Addr)
+			if client, ok := dc.clients[event.Service.Addr]; ok {
+				_ = client.Close()
+				delete(dc.clients, event.Service.Addr)
+			}
+		}
+		dc.mu.Unlock()
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(ss []string, s string) []string {
+	out := ss[:0]
+	for _, v := range ss {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (dc *DiscoveryClient) clientFor(addr string) (*Client, error) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if client, ok := dc.clients[addr]; ok && client.IsAvailable() {
+		return client, nil
+	}
+	client, err := Dial("tcp", addr, dc.opt)
+	if err != nil {
+		return nil, err
+	}
+	dc.clients[addr] = client
+	return client, nil
+}
+
+// Call resolves one server for serviceMethod via selector and invokes it
+// there.
+func (dc *DiscoveryClient) Call(serviceMethod string, args, reply interface{}) error {
+	dc.mu.Lock()
+	servers := append([]string(nil), dc.servers...)
+	dc.mu.Unlock()
+
+	addr := dc.selector.Select(servers)
+	if addr == "" {
+		return errors.New("rpc discovery: no available servers for " + dc.service)
+	}
+	client, err := dc.clientFor(addr)
+	if err != nil {
+		return err
+	}
+	return client.Call(serviceMethod, args, reply)
+}
+
+// Close stops watching the registry and closes every pooled connection.
+func (dc *DiscoveryClient) Close() error {
+	dc.watcher.Stop()
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for _, client := range dc.clients {
+		_ = client.Close()
+	}
+	return nil
+}
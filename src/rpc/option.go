@@ -0,0 +1,25 @@
+package rpc
+
+import "Prpc/src/codec"
+
+// MagicNumber marks this connection as speaking the Prpc protocol. It is
+// carried in Option and checked before CodecType, so a server can reject
+// anything that isn't one of its own clients before it ever looks at the
+// codec.
+const MagicNumber = 0x70727063
+
+// Option is exchanged once, in the clear with encoding/gob, right after a
+// connection is established. It tells the server which Codec the rest of
+// the connection (every Header/body pair that follows) will be encoded
+// with.
+type Option struct {
+	MagicNumber int
+	CodecType   codec.Type
+}
+
+// DefaultOption is what Dial and NewDiscoveryClient use when a caller
+// doesn't supply one. NewClient itself requires a non-nil Option.
+var DefaultOption = &Option{
+	MagicNumber: MagicNumber,
+	CodecType:   codec.GobType,
+}
@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Selector picks one address out of the pool a DiscoveryClient currently
+// knows about for a given call.
+type Selector interface {
+	Select(servers []string) string
+}
+
+// RoundRobinSelector cycles through servers in order, wrapping around.
+type RoundRobinSelector struct {
+	mu    sync.Mutex
+	index int
+}
+
+func (s *RoundRobinSelector) Select(servers []string) string {
+	if len(servers) == 0 {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	addr := servers[s.index%len(servers)]
+	s.index++
+	return addr
+}
+
+// RandomSelector picks a uniformly random server on every call.
+type RandomSelector struct{}
+
+func (RandomSelector) Select(servers []string) string {
+	if len(servers) == 0 {
+		return ""
+	}
+	return servers[rand.Intn(len(servers))]
+}
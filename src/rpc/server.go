@@ -0,0 +1,423 @@
+package rpc
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"Prpc/src/codec"
+	"Prpc/src/registry"
+)
+
+// Server serves RPC requests for a set of registered services. The zero
+// value is usable; DefaultServer is provided for the common single-server
+// case.
+type Server struct {
+	serviceMap sync.Map // map[string]*service
+
+	mu         sync.Mutex
+	addr       string
+	bound      bool
+	lis        net.Listener
+	reg        registry.Registry
+	registered []*registry.Service
+	pending    []*registry.Service // queued by Register until Bind knows addr
+	conns      map[io.Closer]struct{}
+}
+
+func NewServer() *Server {
+	return &Server{}
+}
+
+// DefaultServer is the default instance used by the package-level Accept.
+var DefaultServer = NewServer()
+
+// Bind records lis's address, then publishes to the registry any
+// services Register queued while the address was still unknown - the
+// common case, since ListenServer.Start creates the listener (and calls
+// Bind via Accept) only after the caller has already called Register.
+func (s *Server) Bind(lis net.Listener) {
+	s.mu.Lock()
+	s.addr = lis.Addr().String()
+	s.lis = lis
+	s.bound = true
+	reg := s.reg
+	pending := s.pending
+	s.pending = nil
+	addr := s.addr
+	s.mu.Unlock()
+
+	for _, svc := range pending {
+		svc.Addr = addr
+		if err := reg.Register(svc); err != nil {
+			log.Println("rpc server: registry publish error:", err)
+			continue
+		}
+		s.mu.Lock()
+		s.registered = append(s.registered, svc)
+		s.mu.Unlock()
+	}
+}
+
+// Register publishes rcvr's exported methods under name, so clients can
+// call them as "name.MethodName". When reg is non-nil, it also advertises
+// name and the server's address in reg, deregistered again by Stop. If
+// the server hasn't been bound to a listener yet (Bind), the address is
+// unknown, so publishing is queued until Bind supplies it instead of
+// advertising an empty Addr.
+func (s *Server) Register(name string, rcvr interface{}, reg registry.Registry) error {
+	svc := newService(name, rcvr)
+	if _, dup := s.serviceMap.LoadOrStore(name, svc); dup {
+		return errors.New("rpc: service already defined: " + name)
+	}
+	if reg == nil {
+		return nil
+	}
+
+	svcInfo := &registry.Service{Name: name, Methods: svc.methodNames()}
+
+	s.mu.Lock()
+	if !s.bound {
+		s.reg = reg
+		s.pending = append(s.pending, svcInfo)
+		s.mu.Unlock()
+		return nil
+	}
+	addr := s.addr
+	s.mu.Unlock()
+
+	svcInfo.Addr = addr
+	if err := reg.Register(svcInfo); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.reg = reg
+	s.registered = append(s.registered, svcInfo)
+	s.mu.Unlock()
+	return nil
+}
+
+func Register(name string, rcvr interface{}, reg registry.Registry) error {
+	return DefaultServer.Register(name, rcvr, reg)
+}
+
+// Stop deregisters every service this server published and closes its
+// bound listener, if any.
+func (s *Server) Stop() error {
+	s.mu.Lock()
+	reg, registered, lis := s.reg, s.registered, s.lis
+	s.mu.Unlock()
+
+	for _, svc := range registered {
+		if err := reg.Deregister(svc); err != nil {
+			log.Println("rpc server: deregister error:", err)
+		}
+	}
+	if lis == nil {
+		return nil
+	}
+	return lis.Close()
+}
+
+// Shutdown deregisters and closes the listener (via Stop), then waits up
+// to ctx's deadline for in-flight connections to finish on their own
+// before force-closing whatever's left.
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopErr := s.Stop()
+
+	drained := make(chan struct{})
+	go func() {
+		for {
+			s.mu.Lock()
+			n := len(s.conns)
+			s.mu.Unlock()
+			if n == 0 {
+				close(drained)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+	}()
+
+	select {
+	case <-drained:
+		return stopErr
+	case <-ctx.Done():
+		s.mu.Lock()
+		for c := range s.conns {
+			_ = c.Close()
+		}
+		s.mu.Unlock()
+		if stopErr != nil {
+			return stopErr
+		}
+		return ctx.Err()
+	}
+}
+
+func (s *Server) trackConn(c io.Closer) {
+	s.mu.Lock()
+	if s.conns == nil {
+		s.conns = make(map[io.Closer]struct{})
+	}
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *Server) untrackConn(c io.Closer) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+}
+
+func (s *Server) findService(serviceMethod string) (svc *service, mtype *methodType, err error) {
+	dot := strings.LastIndex(serviceMethod, ".")
+	if dot < 0 {
+		err = errors.New("rpc server: service/method request ill-formed: " + serviceMethod)
+		return
+	}
+	serviceName, methodName := serviceMethod[:dot], serviceMethod[dot+1:]
+	svci, ok := s.serviceMap.Load(serviceName)
+	if !ok {
+		err = errors.New("rpc server: can't find service " + serviceName)
+		return
+	}
+	svc = svci.(*service)
+	mtype = svc.methods[methodName]
+	if mtype == nil {
+		err = errors.New("rpc server: can't find method " + methodName)
+	}
+	return
+}
+
+// Accept accepts connections on lis and serves each one in its own
+// goroutine until lis is closed.
+func (s *Server) Accept(lis net.Listener) {
+	s.Bind(lis)
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			log.Println("rpc server: accept error:", err)
+			return
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+func Accept(lis net.Listener) { DefaultServer.Accept(lis) }
+
+// ServeConn runs the connection handshake (reading the gob-encoded
+// Option) then serves Header/body pairs with whichever Codec the client
+// negotiated, until the connection is closed or a framing error occurs.
+func (s *Server) ServeConn(conn io.ReadWriteCloser) {
+	s.trackConn(conn)
+	defer s.untrackConn(conn)
+	defer func() { _ = conn.Close() }()
+	var opt Option
+	if err := gob.NewDecoder(conn).Decode(&opt); err != nil {
+		log.Println("rpc server: options error:", err)
+		return
+	}
+	if opt.MagicNumber != MagicNumber {
+		log.Printf("rpc server: invalid magic number %x", opt.MagicNumber)
+		return
+	}
+	f := codec.Get(opt.CodecType)
+	if f == nil {
+		log.Printf("rpc server: invalid codec type %s", opt.CodecType)
+		return
+	}
+	s.serveCodec(f(conn))
+}
+
+var invalidRequest = struct{}{}
+
+// serveCodec is the connection's single reader: it reads one Header at a
+// time and fans each out to either the unary request path or the
+// relevant *Stream, keyed by Header.Seq, so unary calls and stream
+// messages can freely interleave on the wire.
+func (s *Server) serveCodec(cc codec.Codec) {
+	sending := new(sync.Mutex) // guards concurrent writes to the connection
+	wg := new(sync.WaitGroup)  // waits for all in-flight requests/streams to finish
+
+	streamsMu := new(sync.Mutex)
+	streams := make(map[uint64]*Stream)
+
+	var loopErr error
+readLoop:
+	for {
+		h, err := s.readRequestHeader(cc)
+		if err != nil {
+			loopErr = err
+			break readLoop
+		}
+
+		switch h.Type {
+		case codec.StreamOpen:
+			var sf StreamFrame
+			_ = cc.ReadBody(&sf)
+			s.openStream(cc, h, sending, streamsMu, streams, wg)
+
+		case codec.StreamMsg, codec.StreamClose, codec.Error:
+			var sf StreamFrame
+			bodyErr := cc.ReadBody(&sf)
+
+			streamsMu.Lock()
+			stream := streams[h.Seq]
+			streamsMu.Unlock()
+			if stream == nil {
+				continue readLoop
+			}
+			if bodyErr != nil {
+				stream.deliver(nil, bodyErr)
+				continue readLoop
+			}
+			switch h.Type {
+			case codec.StreamMsg:
+				stream.deliver(&sf, nil)
+			case codec.StreamClose:
+				stream.deliver(nil, io.EOF)
+			case codec.Error:
+				stream.deliver(nil, errors.New(h.Error))
+			}
+
+		default: // Request
+			req, err := s.readRequest(cc, h)
+			if err != nil {
+				req.h.Error = err.Error()
+				req.h.Type = codec.Response
+				s.sendResponse(cc, req.h, invalidRequest, sending)
+				continue readLoop
+			}
+			wg.Add(1)
+			go s.handleRequest(cc, req, sending, wg)
+		}
+	}
+	// Mirror Client.terminateCalls: the reader is gone, so any stream
+	// blocked in Recv (waiting on frameQueue.pop) must be woken with a
+	// terminal error or its handler goroutine, and wg.Wait below, hang
+	// forever on every mid-stream disconnect.
+	streamsMu.Lock()
+	for _, stream := range streams {
+		stream.deliver(nil, loopErr)
+	}
+	streamsMu.Unlock()
+	wg.Wait()
+	_ = cc.Close()
+}
+
+// openStream dispatches a StreamOpen to its service method in its own
+// goroutine, one per active stream, so a slow or long-lived stream never
+// blocks the connection's reader or any other in-flight call.
+func (s *Server) openStream(cc codec.Codec, h *codec.Header, sending *sync.Mutex, streamsMu *sync.Mutex, streams map[uint64]*Stream, wg *sync.WaitGroup) {
+	svc, mtype, err := s.findService(h.ServiceMethod)
+	if err != nil || !mtype.IsStream {
+		if err == nil {
+			err = errors.New("rpc server: " + h.ServiceMethod + " is not a stream method")
+		}
+		errHeader := &codec.Header{ServiceMethod: h.ServiceMethod, Seq: h.Seq, Type: codec.Error, Error: err.Error()}
+		s.sendResponse(cc, errHeader, &StreamFrame{}, sending)
+		return
+	}
+
+	stream := newStream(h.Seq, h.ServiceMethod, cc, sending)
+	streamsMu.Lock()
+	streams[h.Seq] = stream
+	streamsMu.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			streamsMu.Lock()
+			delete(streams, stream.seq)
+			streamsMu.Unlock()
+		}()
+
+		callErr := svc.callStream(mtype, context.Background(), stream)
+		closeHeader := &codec.Header{ServiceMethod: h.ServiceMethod, Seq: stream.seq, Type: codec.StreamClose}
+		if callErr != nil {
+			closeHeader.Type = codec.Error
+			closeHeader.Error = callErr.Error()
+		}
+		s.sendResponse(cc, closeHeader, &StreamFrame{}, sending)
+	}()
+}
+
+// request is one in-flight Header plus its decoded argument and the
+// reply value that will eventually be sent back.
+type request struct {
+	h            *codec.Header
+	argv, replyv reflect.Value
+	mtype        *methodType
+	svc          *service
+}
+
+func (s *Server) readRequestHeader(cc codec.Codec) (*codec.Header, error) {
+	var h codec.Header
+	if err := cc.ReadHeader(&h); err != nil {
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			log.Println("rpc server: read header error:", err)
+		}
+		return nil, err
+	}
+	return &h, nil
+}
+
+// readRequest decodes the body that follows h for a unary call. h has
+// already been read off cc by serveCodec's dispatch loop.
+func (s *Server) readRequest(cc codec.Codec, h *codec.Header) (*request, error) {
+	req := &request{h: h}
+	var err error
+	req.svc, req.mtype, err = s.findService(h.ServiceMethod)
+	if err != nil {
+		_ = cc.ReadBody(nil)
+		return req, err
+	}
+	if req.mtype.IsStream {
+		_ = cc.ReadBody(nil)
+		return req, errors.New("rpc server: " + h.ServiceMethod + " is a stream method, call it with Stream instead of Call")
+	}
+	req.argv = req.mtype.newArgv()
+	argvi := req.argv.Interface()
+	if req.argv.Kind() != reflect.Ptr {
+		argvi = req.argv.Addr().Interface()
+	}
+	if err = cc.ReadBody(argvi); err != nil {
+		log.Println("rpc server: read body error:", err)
+		return req, err
+	}
+	return req, nil
+}
+
+func (s *Server) sendResponse(cc codec.Codec, h *codec.Header, body interface{}, sending *sync.Mutex) {
+	sending.Lock()
+	defer sending.Unlock()
+	if err := cc.Write(h, body); err != nil {
+		log.Println("rpc server: write response error:", err)
+	}
+}
+
+func (s *Server) handleRequest(cc codec.Codec, req *request, sending *sync.Mutex, wg *sync.WaitGroup) {
+	defer wg.Done()
+	req.h.Type = codec.Response
+	req.replyv = req.mtype.newReplyv()
+	if err := req.svc.call(req.mtype, req.argv, req.replyv); err != nil {
+		req.h.Error = err.Error()
+		s.sendResponse(cc, req.h, invalidRequest, sending)
+		return
+	}
+	s.sendResponse(cc, req.h, req.replyv.Interface(), sending)
+}
@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"go/ast"
+	"log"
+	"reflect"
+)
+
+var (
+	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typeOfStream  = reflect.TypeOf((*Stream)(nil))
+)
+
+// methodType describes one exported method of a registered receiver.
+// Most are unary: func (t *T) M(argv, replyv *ArgType) error. A method
+// shaped like func (t *T) M(ctx context.Context, stream *Stream) error
+// is a streaming one instead, recorded with IsStream set and ArgType/
+// ReplyType left unused.
+type methodType struct {
+	method    reflect.Method
+	ArgType   reflect.Type
+	ReplyType reflect.Type
+	IsStream  bool
+}
+
+func (m *methodType) newArgv() reflect.Value {
+	var argv reflect.Value
+	if m.ArgType.Kind() == reflect.Ptr {
+		argv = reflect.New(m.ArgType.Elem())
+	} else {
+		argv = reflect.New(m.ArgType).Elem()
+	}
+	return argv
+}
+
+func (m *methodType) newReplyv() reflect.Value {
+	replyv := reflect.New(m.ReplyType.Elem())
+	switch m.ReplyType.Elem().Kind() {
+	case reflect.Map:
+		replyv.Elem().Set(reflect.MakeMap(m.ReplyType.Elem()))
+	case reflect.Slice:
+		replyv.Elem().Set(reflect.MakeSlice(m.ReplyType.Elem(), 0, 0))
+	}
+	return replyv
+}
+
+// service wraps a receiver value so its exported, RPC-shaped methods can
+// be invoked by name.
+type service struct {
+	name    string
+	typ     reflect.Type
+	rcvr    reflect.Value
+	methods map[string]*methodType
+}
+
+// newService wraps rcvr under name. An empty name falls back to rcvr's
+// own type name, which is what unqualified Server.Register calls used
+// before services could be published under a registry name that differs
+// from the Go type.
+func newService(name string, rcvr interface{}) *service {
+	s := new(service)
+	s.rcvr = reflect.ValueOf(rcvr)
+	if name == "" {
+		name = reflect.Indirect(s.rcvr).Type().Name()
+	}
+	s.name = name
+	s.typ = reflect.TypeOf(rcvr)
+	if !ast.IsExported(s.name) {
+		log.Fatalf("rpc server: %s is not a valid service name", s.name)
+	}
+	s.registerMethods()
+	return s
+}
+
+// methodNames lists the exported, RPC-shaped methods found on the
+// receiver, for advertising alongside the service in a registry.
+func (s *service) methodNames() []string {
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (s *service) registerMethods() {
+	s.methods = make(map[string]*methodType)
+	for i := 0; i < s.typ.NumMethod(); i++ {
+		method := s.typ.Method(i)
+		mType := method.Type
+		if mType.NumIn() != 3 || mType.NumOut() != 1 || mType.Out(0) != typeOfError {
+			continue
+		}
+
+		firstIn, secondIn := mType.In(1), mType.In(2)
+		if firstIn == typeOfContext && secondIn == typeOfStream {
+			s.methods[method.Name] = &methodType{method: method, IsStream: true}
+			log.Printf("rpc server: registered stream method %s.%s\n", s.name, method.Name)
+			continue
+		}
+
+		if !isExportedOrBuiltinType(firstIn) || !isExportedOrBuiltinType(secondIn) {
+			continue
+		}
+		s.methods[method.Name] = &methodType{
+			method:    method,
+			ArgType:   firstIn,
+			ReplyType: secondIn,
+		}
+		log.Printf("rpc server: registered method %s.%s\n", s.name, method.Name)
+	}
+}
+
+func isExportedOrBuiltinType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return ast.IsExported(t.Name()) || t.PkgPath() == ""
+}
+
+func (s *service) call(m *methodType, argv, replyv reflect.Value) error {
+	f := m.method.Func
+	returnValues := f.Call([]reflect.Value{s.rcvr, argv, replyv})
+	if errInter := returnValues[0].Interface(); errInter != nil {
+		return errInter.(error)
+	}
+	return nil
+}
+
+func (s *service) callStream(m *methodType, ctx context.Context, stream *Stream) error {
+	f := m.method.Func
+	returnValues := f.Call([]reflect.Value{s.rcvr, reflect.ValueOf(ctx), reflect.ValueOf(stream)})
+	if errInter := returnValues[0].Interface(); errInter != nil {
+		return errInter.(error)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"encoding/json"
+	"sync"
+
+	"Prpc/src/codec"
+)
+
+// StreamFrame is the concrete body type written for every StreamOpen/
+// StreamMsg/StreamClose frame. Using one concrete type (rather than the
+// stream's actual payload type) lets the connection's single reader
+// goroutine decode a frame's body before it knows - or cares - what Go
+// type the handler on the other side will eventually unmarshal Payload
+// into.
+type StreamFrame struct {
+	Payload []byte
+}
+
+// Stream is the server-side handle passed to a func(ctx, *Stream) error
+// service method. Send/Recv/CloseSend all share the underlying
+// connection with every other call and stream on it, multiplexed by Seq.
+type Stream struct {
+	seq           uint64
+	serviceMethod string
+	cc            codec.Codec
+	sending       *sync.Mutex
+
+	queue *frameQueue
+
+	closeSendOnce sync.Once
+}
+
+func newStream(seq uint64, serviceMethod string, cc codec.Codec, sending *sync.Mutex) *Stream {
+	return &Stream{
+		seq:           seq,
+		serviceMethod: serviceMethod,
+		cc:            cc,
+		sending:       sending,
+		queue:         newFrameQueue(),
+	}
+}
+
+// deliver is called by the connection's read loop to hand this stream an
+// incoming message (frame, nil) or a terminal condition (nil, err). It
+// only ever pushes onto queue, so a handler that's slow to call Recv
+// can't block the read loop that every other call and stream shares.
+func (s *Stream) deliver(frame *StreamFrame, err error) {
+	s.queue.push(frame, err)
+}
+
+// Send marshals v as JSON and writes it as a StreamMsg frame.
+func (s *Stream) Send(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.sending.Lock()
+	defer s.sending.Unlock()
+	h := &codec.Header{ServiceMethod: s.serviceMethod, Seq: s.seq, Type: codec.StreamMsg}
+	return s.cc.Write(h, &StreamFrame{Payload: data})
+}
+
+// Recv blocks for the next message sent by the peer, unmarshaling its
+// payload into v. It returns io.EOF once the peer calls CloseSend.
+func (s *Stream) Recv(v interface{}) error {
+	frame, err := s.queue.pop()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(frame.Payload, v)
+}
+
+// CloseSend tells the peer this side has no more messages to send. It is
+// safe to call more than once; only the first call has any effect.
+func (s *Stream) CloseSend() error {
+	var err error
+	s.closeSendOnce.Do(func() {
+		s.sending.Lock()
+		defer s.sending.Unlock()
+		h := &codec.Header{ServiceMethod: s.serviceMethod, Seq: s.seq, Type: codec.StreamClose}
+		err = s.cc.Write(h, &StreamFrame{})
+	})
+	return err
+}
+
+// frameQueue is an unbounded inbox of StreamFrames shared between the
+// connection's read loop and whatever goroutine is calling Recv. push is
+// called from the read loop and must never block on a slow (or absent)
+// reader, so frames pile up in memory here instead of on a fixed-size
+// channel; pop drains them in order and only surfaces a terminal error
+// once every frame delivered before it has been returned.
+type frameQueue struct {
+	mu     sync.Mutex
+	frames []*StreamFrame
+	err    error
+	ready  chan struct{}
+}
+
+func newFrameQueue() *frameQueue {
+	return &frameQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *frameQueue) push(frame *StreamFrame, err error) {
+	q.mu.Lock()
+	if q.err == nil {
+		if err != nil {
+			q.err = err
+		} else {
+			q.frames = append(q.frames, frame)
+		}
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *frameQueue) pop() (*StreamFrame, error) {
+	for {
+		q.mu.Lock()
+		if len(q.frames) > 0 {
+			frame := q.frames[0]
+			q.frames = q.frames[1:]
+			q.mu.Unlock()
+			return frame, nil
+		}
+		err := q.err
+		q.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		<-q.ready
+	}
+}
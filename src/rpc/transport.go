@@ -0,0 +1,34 @@
+package rpc
+
+import (
+	"context"
+	"net"
+)
+
+// ListenServer adapts a Server to the Start()/Stop(ctx) shape app.App
+// expects (see transport.Server) by owning the net.Listener Accept
+// needs.
+type ListenServer struct {
+	Server  *Server
+	Network string
+	Addr    string
+}
+
+func NewListenServer(s *Server, network, addr string) *ListenServer {
+	return &ListenServer{Server: s, Network: network, Addr: addr}
+}
+
+// Start listens on Network/Addr and serves connections until Stop closes
+// the listener.
+func (l *ListenServer) Start() error {
+	lis, err := net.Listen(l.Network, l.Addr)
+	if err != nil {
+		return err
+	}
+	l.Server.Accept(lis) // blocks; Bind happens inside Accept
+	return nil
+}
+
+func (l *ListenServer) Stop(ctx context.Context) error {
+	return l.Server.Shutdown(ctx)
+}
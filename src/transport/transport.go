@@ -0,0 +1,17 @@
+// Package transport defines the lifecycle contract app.App manages.
+package transport
+
+import "context"
+
+// Server is anything app.App can run and gracefully stop. gee's
+// Engine.Server(addr) and rpc's ListenServer both satisfy this
+// structurally, without importing this package, so the HTTP and RPC
+// layers stay decoupled from the app package that wires them together.
+type Server interface {
+	// Start blocks until the server stops (Stop was called) or it fails
+	// to keep serving.
+	Start() error
+	// Stop asks the server to drain in-flight work and shut down,
+	// aborting anything still running when ctx is done.
+	Stop(ctx context.Context) error
+}